@@ -0,0 +1,170 @@
+// Package archive fetches and sanitizes bookmarked pages so they remain
+// readable offline, storing the cleaned HTML on disk and recording the
+// result against the bookmark row.
+package archive
+
+import (
+	"Bookmark-RESTful/repository"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+const (
+	maxRetries      = 3
+	retryBackoff    = 2 * time.Second
+	fetchTimeout    = 15 * time.Second
+	excerptMaxChars = 280
+)
+
+// Job describes a single page to archive.
+type Job struct {
+	BookmarkID int
+	UserID     int
+	URL        string
+}
+
+// Worker archives bookmarked pages in the background with bounded
+// concurrency and retries, so archival never blocks the HTTP response.
+type Worker struct {
+	db   *sql.DB
+	dir  string
+	jobs chan Job
+	wg   sync.WaitGroup
+}
+
+// NewWorker starts a pool of concurrency goroutines that write archived
+// pages under dir, keyed by bookmark ID, and record the result via db.
+func NewWorker(db *sql.DB, dir string, concurrency int) *Worker {
+	w := &Worker{db: db, dir: dir, jobs: make(chan Job, 100)}
+	for i := 0; i < concurrency; i++ {
+		w.wg.Add(1)
+		go w.loop()
+	}
+	return w
+}
+
+// Enqueue schedules a page for archival. It never blocks the caller: if the
+// queue is full, the job is dropped and logged rather than stalling the HTTP
+// response that triggered it.
+func (w *Worker) Enqueue(job Job) {
+	select {
+	case w.jobs <- job:
+	default:
+		log.Printf("archive: queue full, dropping archive job for bookmark %d", job.BookmarkID)
+	}
+}
+
+func (w *Worker) loop() {
+	defer w.wg.Done()
+	for job := range w.jobs {
+		if err := w.process(job); err != nil {
+			log.Printf("archive: could not archive bookmark %d: %v", job.BookmarkID, err)
+		}
+	}
+}
+
+func (w *Worker) process(job Job) error {
+	body, err := fetchWithRetry(job.URL)
+	if err != nil {
+		return fmt.Errorf("could not fetch %s: %w", job.URL, err)
+	}
+
+	clean := bluemonday.UGCPolicy().SanitizeBytes(extractReadable(body))
+
+	bookmarkDir := filepath.Join(w.dir, strconv.Itoa(job.BookmarkID))
+	if err := os.MkdirAll(bookmarkDir, 0o755); err != nil {
+		return fmt.Errorf("could not create archive dir: %w", err)
+	}
+
+	archivePath := filepath.Join(bookmarkDir, "index.html")
+	if err := os.WriteFile(archivePath, clean, 0o644); err != nil {
+		return fmt.Errorf("could not write archive: %w", err)
+	}
+
+	excerpt := excerptFrom(clean)
+	// The image is looked up in the fetched page rather than the sanitized
+	// copy, since sanitizing drops <meta> tags (and with them og:image); only
+	// http(s) image URLs are kept.
+	imageURL := extractImageURL(body, job.URL)
+	if !strings.HasPrefix(imageURL, "http://") && !strings.HasPrefix(imageURL, "https://") {
+		imageURL = ""
+	}
+
+	// Full-page screenshot capture needs a headless browser (e.g. chromedp),
+	// which this worker deliberately does not pull in; imageURL is the best
+	// representative image extracted from the page markup (og:image, or the
+	// first <img>) and is a stand-in until a follow-up request adds a
+	// dedicated rendering step.
+	return repository.UpdateBookmarkArchive(context.Background(), w.db, job.BookmarkID, archivePath, excerpt, imageURL)
+}
+
+// fetchClient is shared by all fetches so every one dials through
+// safeDialContext, which re-resolves and re-checks the target (including on
+// redirect, since each new connection is dialed separately) to block SSRF
+// against loopback/link-local/private addresses.
+var fetchClient = &http.Client{
+	Timeout:   fetchTimeout,
+	Transport: &http.Transport{DialContext: safeDialContext},
+}
+
+func fetchWithRetry(rawURL string) ([]byte, error) {
+	if _, err := validateFetchURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(retryBackoff * time.Duration(attempt))
+		}
+
+		resp, err := fetchClient.Get(rawURL)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			lastErr = fmt.Errorf("unexpected status %d", resp.StatusCode)
+			continue
+		}
+		return body, nil
+	}
+	return nil, lastErr
+}
+
+// excerptFrom derives a short plain-text preview from sanitized HTML.
+func excerptFrom(cleanHTML []byte) string {
+	text := bluemonday.StrictPolicy().SanitizeBytes(cleanHTML)
+	s := strings.Join(strings.Fields(string(text)), " ")
+	return truncateRunes(s, excerptMaxChars)
+}
+
+// truncateRunes cuts s to at most n runes, so a multi-byte UTF-8 character
+// is never split across the boundary.
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n])
+}
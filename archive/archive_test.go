@@ -0,0 +1,39 @@
+package archive
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestExcerptFromTruncatesAtExcerptMaxChars(t *testing.T) {
+	html := []byte("<p>" + strings.Repeat("a", excerptMaxChars+50) + "</p>")
+
+	got := excerptFrom(html)
+
+	if n := utf8.RuneCountInString(got); n != excerptMaxChars {
+		t.Errorf("excerptFrom returned %d runes, want %d", n, excerptMaxChars)
+	}
+}
+
+func TestExcerptFromCutsOnRuneBoundary(t *testing.T) {
+	// "é" is two bytes in UTF-8; repeating it past excerptMaxChars forces a
+	// byte-index cut to land mid-rune unless truncation is rune-aware.
+	html := []byte("<p>" + strings.Repeat("é", excerptMaxChars+10) + "</p>")
+
+	got := excerptFrom(html)
+
+	if !utf8.ValidString(got) {
+		t.Fatalf("excerptFrom produced invalid UTF-8: %q", got)
+	}
+	if n := utf8.RuneCountInString(got); n != excerptMaxChars {
+		t.Errorf("excerptFrom returned %d runes, want %d", n, excerptMaxChars)
+	}
+}
+
+func TestTruncateRunesNoOpBelowLimit(t *testing.T) {
+	const s = "short string"
+	if got := truncateRunes(s, 280); got != s {
+		t.Errorf("truncateRunes(%q, 280) = %q, want unchanged", s, got)
+	}
+}
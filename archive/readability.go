@@ -0,0 +1,62 @@
+package archive
+
+import (
+	"bytes"
+
+	"golang.org/x/net/html"
+)
+
+// skippedTags lists elements whose entire subtree is chrome rather than
+// article content (navigation, ads, scripts, styling) and should not appear
+// in the archived, readable version of a page.
+var skippedTags = map[string]bool{
+	"script": true,
+	"style":  true,
+	"nav":    true,
+	"header": true,
+	"footer": true,
+	"aside":  true,
+	"form":   true,
+	"iframe": true,
+}
+
+// extractReadable strips navigation, ad, and script/style chrome from a
+// fetched page so the archived copy is closer to the article body, mirroring
+// the "readability" extraction pass that tools like Shiori run before
+// sanitizing. It is a lightweight tag-denylist, not a full readability
+// scorer, but removes the most common sources of clutter.
+func extractReadable(body []byte) []byte {
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+	var out bytes.Buffer
+	var skipDepth int
+	var skipTag string
+
+	for {
+		tt := tokenizer.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+
+		tok := tokenizer.Token()
+
+		if skipDepth > 0 {
+			if tt == html.StartTagToken && tok.Data == skipTag {
+				skipDepth++
+			} else if tt == html.EndTagToken && tok.Data == skipTag {
+				skipDepth--
+			}
+			continue
+		}
+
+		if (tt == html.StartTagToken || tt == html.SelfClosingTagToken) && skippedTags[tok.Data] {
+			if tt == html.StartTagToken {
+				skipDepth = 1
+				skipTag = tok.Data
+			}
+			continue
+		}
+
+		out.WriteString(tok.String())
+	}
+	return out.Bytes()
+}
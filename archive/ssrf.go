@@ -0,0 +1,79 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// validateFetchURL rejects anything that isn't a plain http(s) URL with a
+// host, before the archiver ever attempts to fetch it.
+func validateFetchURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("URL has no host")
+	}
+	return u, nil
+}
+
+// isDisallowedIP reports whether ip must never be fetched on the archiver's
+// behalf: loopback, link-local (including the 169.254.169.254 cloud
+// metadata endpoint), RFC1918/ULA private ranges, or other non-routable
+// addresses.
+func isDisallowedIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsPrivate() ||
+		ip.IsUnspecified() ||
+		ip.IsMulticast()
+}
+
+// safeDialContext is used as the archiver's http.Transport.DialContext. It
+// resolves the host itself, rejects the connection if any candidate address
+// is loopback/link-local/private, and then dials that already-checked IP
+// directly (rather than the hostname again), so a DNS answer that changes
+// between the check and the connect — DNS rebinding — can't be used to
+// smuggle a request to an internal address.
+func safeDialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("could not resolve %s", host)
+	}
+
+	var dialer net.Dialer
+	var lastErr error
+	for _, resolved := range ips {
+		if isDisallowedIP(resolved.IP) {
+			lastErr = fmt.Errorf("refusing to fetch %s: resolves to disallowed address %s", host, resolved.IP)
+			continue
+		}
+
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(resolved.IP.String(), port))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return conn, nil
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no usable address for %s", host)
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,64 @@
+package archive
+
+import (
+	"bytes"
+	"net/url"
+
+	"golang.org/x/net/html"
+)
+
+// extractImageURL looks for a representative image for the archived page: an
+// Open Graph og:image meta tag, falling back to the first <img> src. It
+// returns an absolute URL resolved against pageURL, or "" if none is found.
+func extractImageURL(pageHTML []byte, pageURL string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(pageHTML))
+	var fallback string
+
+	for {
+		if tokenizer.Next() == html.ErrorToken {
+			break
+		}
+
+		tok := tokenizer.Token()
+		if tok.Data != "meta" && tok.Data != "img" {
+			continue
+		}
+
+		attrs := make(map[string]string, len(tok.Attr))
+		for _, a := range tok.Attr {
+			attrs[a.Key] = a.Val
+		}
+
+		switch tok.Data {
+		case "meta":
+			if attrs["property"] == "og:image" || attrs["name"] == "og:image" {
+				if resolved := resolveImageURL(base, attrs["content"]); resolved != "" {
+					return resolved
+				}
+			}
+		case "img":
+			if fallback == "" {
+				fallback = resolveImageURL(base, attrs["src"])
+			}
+		}
+	}
+	return fallback
+}
+
+// resolveImageURL resolves ref (which may be relative) against base,
+// returning "" if ref is empty or unparsable.
+func resolveImageURL(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	return base.ResolveReference(refURL).String()
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateScopesGrantsHeldScopes(t *testing.T) {
+	err := ValidateScopes([]string{ScopeReadBookmarks}, []string{ScopeReadBookmarks, ScopeWriteBookmarks})
+	if err != nil {
+		t.Errorf("ValidateScopes returned error for a held scope: %v", err)
+	}
+}
+
+func TestValidateScopesRejectsEscalation(t *testing.T) {
+	err := ValidateScopes([]string{ScopeAdmin}, []string{ScopeReadBookmarks})
+	if !errors.Is(err, ErrScopeNotGranted) {
+		t.Errorf("ValidateScopes error = %v, want ErrScopeNotGranted", err)
+	}
+}
+
+func TestValidateScopesRejectsUnknownScope(t *testing.T) {
+	err := ValidateScopes([]string{"write:everything"}, []string{ScopeAdmin})
+	if !errors.Is(err, ErrUnknownScope) {
+		t.Errorf("ValidateScopes error = %v, want ErrUnknownScope", err)
+	}
+}
+
+func TestValidateScopesAdminGrantsAnyKnownScope(t *testing.T) {
+	err := ValidateScopes([]string{ScopeReadBookmarks, ScopeWriteBookmarks, ScopeAdmin}, []string{ScopeAdmin})
+	if err != nil {
+		t.Errorf("ValidateScopes returned error for an admin caller: %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"Bookmark-RESTful/repository"
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+)
+
+type contextKey string
+
+const userContextKey contextKey = "user"
+const scopesContextKey contextKey = "scopes"
+
+// Middleware validates the Authorization header on protected routes,
+// accepting either "Bearer <jwt>" (issued by POST /auth/login or
+// /auth/refresh) or "Bearer <api-key>" (issued by POST /auth/keys), and
+// attaches the resolved user and scopes to the request context.
+func (tm *TokenManager) Middleware(db *sql.DB) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				http.Error(w, "Missing Authorization header", http.StatusUnauthorized)
+				return
+			}
+
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				http.Error(w, "Invalid Authorization header format", http.StatusUnauthorized)
+				return
+			}
+			credential := parts[1]
+
+			if user, scopes, ok := tm.decodeAccessToken(r.Context(), db, credential); ok {
+				next.ServeHTTP(w, r.WithContext(withUserAndScopes(r.Context(), user, scopes)))
+				return
+			}
+
+			user, scopes, err := repository.GetUserByAPIKeySecret(r.Context(), db, credential)
+			if err != nil {
+				if err == repository.ErrInvalidAPIKey {
+					http.Error(w, "Invalid API key", http.StatusUnauthorized)
+					return
+				}
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(withUserAndScopes(r.Context(), user, scopes)))
+		})
+	}
+}
+
+// decodeAccessToken verifies a JWT access token and resolves the user it was
+// issued for. ok is false for anything that isn't a valid, current token,
+// letting the caller fall back to treating the credential as an API key.
+func (tm *TokenManager) decodeAccessToken(ctx context.Context, db *sql.DB, tokenStr string) (user *repository.User, scopes []string, ok bool) {
+	token, err := tm.auth.Decode(tokenStr)
+	if err != nil || token == nil {
+		return nil, nil, false
+	}
+
+	rawID, found := token.Get("user_id")
+	if !found {
+		return nil, nil, false
+	}
+	userID, ok := toInt(rawID)
+	if !ok {
+		return nil, nil, false
+	}
+
+	u, err := repository.GetUserByID(ctx, db, userID)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	rawScopes, _ := token.Get("scopes")
+	return u, toStringSlice(rawScopes), true
+}
+
+func toInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case float64:
+		return int(n), true
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	default:
+		return 0, false
+	}
+}
+
+func toStringSlice(v interface{}) []string {
+	raw, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(raw))
+	for _, item := range raw {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func withUserAndScopes(ctx context.Context, user *repository.User, scopes []string) context.Context {
+	ctx = context.WithValue(ctx, userContextKey, user)
+	return context.WithValue(ctx, scopesContextKey, scopes)
+}
+
+// UserFromContext returns the authenticated user attached by Middleware.
+func UserFromContext(ctx context.Context) *repository.User {
+	user, _ := ctx.Value(userContextKey).(*repository.User)
+	return user
+}
+
+// ScopesFromContext returns the scopes granted to the credential used on this request.
+func ScopesFromContext(ctx context.Context) []string {
+	scopes, _ := ctx.Value(scopesContextKey).([]string)
+	return scopes
+}
+
+// RequireScope returns middleware that rejects requests whose credential
+// doesn't carry the given scope (or the admin scope, which implies all others).
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, s := range ScopesFromContext(r.Context()) {
+				if s == scope || s == ScopeAdmin {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			http.Error(w, "Insufficient scope", http.StatusForbidden)
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migration is a single numbered schema change, with its forward (up) and
+// reverse (down) SQL loaded from db/migrations.
+type migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// loadMigrations reads db/migrations, pairing each NNNN_name.up.sql with its
+// NNNN_name.down.sql, ordered by version.
+func loadMigrations() ([]migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, fmt.Errorf("could not read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		version, label, direction, err := parseMigrationFilename(name)
+		if err != nil {
+			return nil, err
+		}
+
+		contents, err := migrationFiles.ReadFile("migrations/" + name)
+		if err != nil {
+			return nil, fmt.Errorf("could not read migration %s: %w", name, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{Version: version, Name: label}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(contents)
+		} else {
+			m.Down = string(contents)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// parseMigrationFilename splits "0003_add_tags.up.sql" into version 3,
+// name "add_tags", and direction "up".
+func parseMigrationFilename(name string) (version int, label, direction string, err error) {
+	trimmed := strings.TrimSuffix(name, ".sql")
+	parts := strings.SplitN(trimmed, "_", 2)
+	if len(parts) != 2 {
+		return 0, "", "", fmt.Errorf("malformed migration filename %q", name)
+	}
+
+	version, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", "", fmt.Errorf("malformed migration filename %q: %w", name, err)
+	}
+
+	rest := parts[1]
+	switch {
+	case strings.HasSuffix(rest, ".up"):
+		return version, strings.TrimSuffix(rest, ".up"), "up", nil
+	case strings.HasSuffix(rest, ".down"):
+		return version, strings.TrimSuffix(rest, ".down"), "down", nil
+	default:
+		return 0, "", "", fmt.Errorf("malformed migration filename %q", name)
+	}
+}
+
+// ensureSchemaMigrationsTable creates the table that tracks applied versions.
+func ensureSchemaMigrationsTable(ctx context.Context, database *sql.DB) error {
+	_, err := database.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+	)
+	return err
+}
+
+// appliedVersions returns the set of migration versions already applied.
+func appliedVersions(ctx context.Context, database *sql.DB) (map[int]bool, error) {
+	rows, err := database.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// Migrate applies every migration that hasn't been recorded in
+// schema_migrations yet, each in its own transaction, in version order.
+func Migrate(ctx context.Context, database *sql.DB) error {
+	if err := ensureSchemaMigrationsTable(ctx, database); err != nil {
+		return fmt.Errorf("could not prepare schema_migrations: %w", err)
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	applied, err := appliedVersions(ctx, database)
+	if err != nil {
+		return fmt.Errorf("could not read applied migrations: %w", err)
+	}
+
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+
+		if err := runMigration(ctx, database, m, m.Up); err != nil {
+			return fmt.Errorf("could not apply migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+// Rollback reverts the most recently applied migration.
+func Rollback(ctx context.Context, database *sql.DB) error {
+	if err := ensureSchemaMigrationsTable(ctx, database); err != nil {
+		return fmt.Errorf("could not prepare schema_migrations: %w", err)
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	applied, err := appliedVersions(ctx, database)
+	if err != nil {
+		return fmt.Errorf("could not read applied migrations: %w", err)
+	}
+
+	latest := -1
+	for _, m := range migrations {
+		if applied[m.Version] && m.Version > latest {
+			latest = m.Version
+		}
+	}
+	if latest == -1 {
+		return nil
+	}
+
+	for _, m := range migrations {
+		if m.Version != latest {
+			continue
+		}
+		tx, err := database.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("could not start the transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		if err := execStatements(ctx, tx, m.Down); err != nil {
+			return fmt.Errorf("could not revert migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", m.Version); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+	return nil
+}
+
+// runMigration applies a single migration's SQL and records it as applied,
+// all within one transaction.
+func runMigration(ctx context.Context, database *sql.DB, m migration, sqlText string) error {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not start the transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := execStatements(ctx, tx, sqlText); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO schema_migrations (version, name) VALUES (?, ?)", m.Version, m.Name,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// execStatements runs each semicolon-separated statement in sqlText in turn,
+// since the mysql driver does not execute multiple statements in one call
+// unless multiStatements is enabled on the connection.
+func execStatements(ctx context.Context, tx *sql.Tx, sqlText string) error {
+	for _, stmt := range strings.Split(sqlText, ";") {
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Status reports each known migration's version, name, and whether it has
+// been applied, in version order.
+type Status struct {
+	Version int
+	Name    string
+	Applied bool
+}
+
+// MigrationStatus returns the status of every known migration.
+func MigrationStatus(ctx context.Context, database *sql.DB) ([]Status, error) {
+	if err := ensureSchemaMigrationsTable(ctx, database); err != nil {
+		return nil, fmt.Errorf("could not prepare schema_migrations: %w", err)
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedVersions(ctx, database)
+	if err != nil {
+		return nil, fmt.Errorf("could not read applied migrations: %w", err)
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	for _, m := range migrations {
+		statuses = append(statuses, Status{Version: m.Version, Name: m.Name, Applied: applied[m.Version]})
+	}
+	return statuses, nil
+}
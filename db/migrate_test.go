@@ -0,0 +1,35 @@
+package db
+
+import "testing"
+
+func TestParseMigrationFilename(t *testing.T) {
+	cases := []struct {
+		name          string
+		wantVersion   int
+		wantLabel     string
+		wantDirection string
+	}{
+		{"0001_create_users.up.sql", 1, "create_users", "up"},
+		{"0001_create_users.down.sql", 1, "create_users", "down"},
+		{"0006_add_bookmarks_fulltext_index.up.sql", 6, "add_bookmarks_fulltext_index", "up"},
+	}
+
+	for _, c := range cases {
+		version, label, direction, err := parseMigrationFilename(c.name)
+		if err != nil {
+			t.Fatalf("parseMigrationFilename(%q) returned error: %v", c.name, err)
+		}
+		if version != c.wantVersion || label != c.wantLabel || direction != c.wantDirection {
+			t.Errorf("parseMigrationFilename(%q) = (%d, %q, %q), want (%d, %q, %q)",
+				c.name, version, label, direction, c.wantVersion, c.wantLabel, c.wantDirection)
+		}
+	}
+}
+
+func TestParseMigrationFilenameMalformed(t *testing.T) {
+	for _, name := range []string{"bogus.sql", "0001_create_users.sql", "abcd_create_users.up.sql"} {
+		if _, _, _, err := parseMigrationFilename(name); err == nil {
+			t.Errorf("parseMigrationFilename(%q) returned nil error, want non-nil", name)
+		}
+	}
+}
@@ -1,88 +1,217 @@
 package handler
 
 import (
+	"Bookmark-RESTful/archive"
+	"Bookmark-RESTful/auth"
+	"Bookmark-RESTful/importer"
 	"Bookmark-RESTful/repository"
-	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // Handler provides the database connection to the HTTP handlers.
 type Handler struct {
 	DB *sql.DB
+
+	// Auth issues and verifies JWT access tokens for Authenticate.
+	Auth *auth.TokenManager
+
+	// ArchiveWorker, if set, receives jobs to fetch and archive a bookmarked
+	// page in the background. Nil disables archival.
+	ArchiveWorker *archive.Worker
 }
 
-// APIKeyMiddleware validates API keys for protected routes.
-func (h *Handler) APIKeyMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Get API key from Authorization header
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Missing Authorization header", http.StatusUnauthorized)
-			return
-		}
+// Authenticate validates the Authorization header for protected routes,
+// accepting either a JWT access token or a named API key, and attaches the
+// resolved user and scopes to the request context.
+func (h *Handler) Authenticate(next http.Handler) http.Handler {
+	return h.Auth.Middleware(h.DB)(next)
+}
+
+// Login handles requests to exchange email/password credentials for a JWT
+// access token and a refresh token.
+// POST /auth/login
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
 
-		// Expected format: "Bearer <api-key>"
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			http.Error(w, "Invalid Authorization header format", http.StatusUnauthorized)
+	user, err := repository.GetUserByEmail(r.Context(), h.DB, body.Email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Invalid email or password", http.StatusUnauthorized)
 			return
 		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
 
-		apiKey := parts[1]
-		user, err := repository.GetUserByAPIKey(h.DB, apiKey)
-		if err != nil {
-			if err == repository.ErrInvalidAPIKey {
-				http.Error(w, "Invalid API key", http.StatusUnauthorized)
-				return
-			}
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(body.Password)); err != nil {
+		http.Error(w, "Invalid email or password", http.StatusUnauthorized)
+		return
+	}
+
+	h.issueSession(w, r, user, []string{auth.ScopeReadBookmarks, auth.ScopeWriteBookmarks})
+}
+
+// Refresh handles requests to exchange a refresh token for a new access
+// token, rotating the refresh token in the process.
+// POST /auth/refresh
+func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user, err := repository.GetUserByRefreshToken(r.Context(), h.DB, body.RefreshToken)
+	if err != nil {
+		if err == repository.ErrInvalidRefreshToken {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
 			return
 		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
 
-		// Add user to request context
-		type contextKey string
-		const userContextKey contextKey = "user"
-		ctx := context.WithValue(r.Context(), userContextKey, user)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+	if err := repository.RevokeRefreshToken(r.Context(), h.DB, body.RefreshToken); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.issueSession(w, r, user, []string{auth.ScopeReadBookmarks, auth.ScopeWriteBookmarks})
 }
 
-// RegenerateAPIKey handles requests to regenerate a user's API key
-// POST /auth/regenerate-key
-func (h *Handler) RegenerateAPIKey(w http.ResponseWriter, r *http.Request) {
-	user := r.Context().Value("user").(*repository.User)
+// issueSession writes a fresh access token and refresh token pair for user.
+func (h *Handler) issueSession(w http.ResponseWriter, r *http.Request, user *repository.User, scopes []string) {
+	accessToken, err := h.Auth.IssueAccessToken(user.ID, scopes)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
 
-	newAPIKey, err := repository.RegenerateAPIKey(h.DB, user.ID)
+	refreshToken, err := repository.CreateRefreshToken(r.Context(), h.DB, user.ID)
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	response := map[string]string{
-		"api_key": newAPIKey,
-		"message": "API key regenerated successfully",
+	response := map[string]interface{}{
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    auth.AccessTokenTTLSeconds(),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateAPIKey handles requests to mint a new named, scoped API key for the
+// current user.
+// POST /auth/keys
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+
+	var body struct {
+		Name      string     `json:"name"`
+		Scopes    []string   `json:"scopes"`
+		ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := auth.ValidateScopes(body.Scopes, auth.ScopesFromContext(r.Context())); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	key, secret, err := repository.CreateAPIKey(r.Context(), h.DB, user.ID, body.Name, body.Scopes, body.ExpiresAt)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
 	}
 
+	response := struct {
+		*repository.APIKey
+		Secret string `json:"api_key"`
+	}{APIKey: key, Secret: secret}
+
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(response)
 }
 
+// ListAPIKeys handles requests to list the current user's API keys.
+// GET /auth/keys
+func (h *Handler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+
+	keys, err := repository.ListAPIKeys(r.Context(), h.DB, user.ID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+// DeleteAPIKey handles requests to revoke one of the current user's API keys.
+// DELETE /auth/keys/{id}
+func (h *Handler) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+
+	keyID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid key ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := repository.DeleteAPIKey(r.Context(), h.DB, user.ID, keyID); err != nil {
+		if err == repository.ErrAPIKeyNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // CreateUser handles requests to create a new user.
 // POST /users
 func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
-	var u repository.User
-	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+	var body struct {
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	user, err := repository.CreateUser(h.DB, u.Name, u.Email)
+	user, err := repository.CreateUser(r.Context(), h.DB, body.Name, body.Email, body.Password)
 	if err != nil {
 		if err == repository.ErrEmailTaken {
 			http.Error(w, err.Error(), http.StatusConflict)
@@ -98,7 +227,7 @@ func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
 
 // CreateBookmark handles requests to create a new bookmark.
 func (h *Handler) CreateBookmark(w http.ResponseWriter, r *http.Request) {
-	user := r.Context().Value("user").(*repository.User)
+	user := auth.UserFromContext(r.Context())
 
 	var bookmark repository.Bookmark
 	if err := json.NewDecoder(r.Body).Decode(&bookmark); err != nil {
@@ -106,31 +235,425 @@ func (h *Handler) CreateBookmark(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bm, err := repository.CreateBookmark(h.DB, user.ID, bookmark.Title, bookmark.URL)
+	bm, err := repository.CreateBookmark(r.Context(), h.DB, user.ID, bookmark.Title, bookmark.URL)
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
+	if h.ArchiveWorker != nil {
+		h.ArchiveWorker.Enqueue(archive.Job{BookmarkID: bm.ID, UserID: user.ID, URL: bm.URL})
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(bm)
 }
 
+// ArchiveBookmark handles requests to (re-)archive a bookmark's page.
+// POST /bookmarks/{id}/archive
+func (h *Handler) ArchiveBookmark(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+
+	bookmarkID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+		return
+	}
+
+	bm, err := repository.GetBookmark(r.Context(), h.DB, user.ID, bookmarkID)
+	if err != nil {
+		if err == repository.ErrBookmarkNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if h.ArchiveWorker == nil {
+		http.Error(w, "Archiving is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+	h.ArchiveWorker.Enqueue(archive.Job{BookmarkID: bm.ID, UserID: user.ID, URL: bm.URL})
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// GetBookmarkContent handles requests to serve a bookmark's archived readable version.
+// GET /bookmarks/{id}/content
+func (h *Handler) GetBookmarkContent(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+
+	bookmarkID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+		return
+	}
+
+	bm, err := repository.GetBookmark(r.Context(), h.DB, user.ID, bookmarkID)
+	if err != nil {
+		if err == repository.ErrBookmarkNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if !bm.Archived || bm.ArchivePath == "" {
+		http.Error(w, "Bookmark has not been archived yet", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	http.ServeFile(w, r, bm.ArchivePath)
+}
+
 // ListBookmarksForCurrentUser handles requests to list current user's bookmarks.
-// GET /bookmarks
+// GET /bookmarks?tag=foo&tag=bar&q=text&limit=&offset=&cursor=
 // Protected Routes (API Key Required)
 func (h *Handler) ListBookmarksForCurrentUser(w http.ResponseWriter, r *http.Request) {
-	user := r.Context().Value("user").(*repository.User)
+	user := auth.UserFromContext(r.Context())
+
+	query := r.URL.Query()
+	tags := query["tag"]
+	q := query.Get("q")
+
+	response := struct {
+		Items      []*repository.Bookmark `json:"items"`
+		NextCursor string                 `json:"next_cursor,omitempty"`
+	}{}
+
+	if len(tags) > 0 || q != "" {
+		limit, offset, perr := parseLimitOffset(query)
+		if perr != nil {
+			http.Error(w, perr.Error(), http.StatusBadRequest)
+			return
+		}
+		bms, err := repository.SearchBookmarks(r.Context(), h.DB, user.ID, q, tags, limit, offset)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		response.Items = bms
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	opts := repository.ListOptions{Cursor: query.Get("cursor")}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		opts.Limit = limit
+	}
+
+	page, err := repository.FetchBookmarks(r.Context(), h.DB, user.ID, opts)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if page.NextCursor != "" {
+		nextURL := *r.URL
+		next := url.Values{}
+		for k, v := range query {
+			next[k] = v
+		}
+		next.Set("cursor", page.NextCursor)
+		nextURL.RawQuery = next.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, nextURL.String()))
+	}
+
+	response.Items = page.Items
+	response.NextCursor = page.NextCursor
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// parseLimitOffset parses the optional limit/offset query parameters, defaulting
+// limit to 0 (no pagination) and offset to 0.
+func parseLimitOffset(query map[string][]string) (limit, offset int, err error) {
+	if v := query["limit"]; len(v) > 0 && v[0] != "" {
+		limit, err = strconv.Atoi(v[0])
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid limit")
+		}
+	}
+	if v := query["offset"]; len(v) > 0 && v[0] != "" {
+		offset, err = strconv.Atoi(v[0])
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid offset")
+		}
+	}
+	return limit, offset, nil
+}
+
+// AddBookmarkTags handles requests to attach one or more tags to a bookmark.
+// POST /bookmarks/{id}/tags
+func (h *Handler) AddBookmarkTags(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+
+	bookmarkID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := repository.AddTagsToBookmark(r.Context(), h.DB, user.ID, bookmarkID, body.Tags); err != nil {
+		if err == repository.ErrBookmarkNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveBookmarkTag handles requests to detach a tag from a bookmark.
+// DELETE /bookmarks/{id}/tags/{tag}
+func (h *Handler) RemoveBookmarkTag(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+
+	bookmarkID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+		return
+	}
+	tag := chi.URLParam(r, "tag")
+
+	if err := repository.RemoveTagsFromBookmark(r.Context(), h.DB, user.ID, bookmarkID, tag); err != nil {
+		if err == repository.ErrBookmarkNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ImportBookmarks handles requests to bulk-import bookmarks from either the
+// Netscape Bookmark File Format or a plain JSON array.
+// POST /bookmarks/import
+func (h *Handler) ImportBookmarks(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+
+	var bookmarks []repository.Bookmark
+	var err error
+
+	if strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		bookmarks, err = importer.ParseJSON(r.Body)
+	} else {
+		bookmarks, err = importer.ParseNetscape(r.Body)
+	}
+	if err != nil {
+		http.Error(w, "Could not parse import file", http.StatusBadRequest)
+		return
+	}
+
+	inserted, skipped, err := repository.BulkCreateBookmarks(r.Context(), h.DB, user.ID, bookmarks)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]int{
+		"inserted": inserted,
+		"skipped":  skipped,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ExportBookmarks handles requests to export all of the current user's
+// bookmarks, as Netscape Bookmark File Format HTML by default or as a JSON
+// array when passed ?format=json.
+// GET /bookmarks/export
+func (h *Handler) ExportBookmarks(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+
+	var bms []*repository.Bookmark
+	cursor := ""
+	for {
+		page, err := repository.FetchBookmarks(r.Context(), h.DB, user.ID, repository.ListOptions{Cursor: cursor})
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		bms = append(bms, page.Items...)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(bms)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="bookmarks.html"`)
+	if err := importer.WriteNetscape(w, bms); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// AddComment handles requests to attach a comment to a bookmark.
+// POST /bookmarks/{id}/comments
+func (h *Handler) AddComment(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+
+	bookmarkID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := repository.AddComment(r.Context(), h.DB, user.ID, bookmarkID, body.Body)
+	if err != nil {
+		if err == repository.ErrBookmarkNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+// ListComments handles requests to list the comments on a bookmark.
+// GET /bookmarks/{id}/comments?limit=&offset=
+func (h *Handler) ListComments(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+
+	bookmarkID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+		return
+	}
+
+	limit, offset, perr := parseLimitOffset(r.URL.Query())
+	if perr != nil {
+		http.Error(w, perr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	comments, err := repository.ListComments(r.Context(), h.DB, user.ID, bookmarkID, limit, offset)
+	if err != nil {
+		if err == repository.ErrBookmarkNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comments)
+}
+
+// UpdateComment handles requests to edit one of the current user's comments.
+// PATCH /comments/{id}
+func (h *Handler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+
+	commentID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := repository.UpdateComment(r.Context(), h.DB, user.ID, commentID, body.Body); err != nil {
+		if err == repository.ErrCommentNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteComment handles requests to delete one of the current user's comments.
+// DELETE /comments/{id}
+func (h *Handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+
+	commentID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := repository.DeleteComment(r.Context(), h.DB, user.ID, commentID); err != nil {
+		if err == repository.ErrCommentNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListTags handles requests to list all distinct tags the current user has created.
+// GET /tags
+func (h *Handler) ListTags(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
 
-	bms, err := repository.FetchBookmarks(h.DB, user.ID)
+	tags, err := repository.ListTags(r.Context(), h.DB, user.ID)
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(bms)
+	json.NewEncoder(w).Encode(tags)
 }
 
 // ListBookmarks handles requests to list a user's bookmarks.
@@ -145,12 +668,12 @@ func (h *Handler) ListBookmarks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bms, err := repository.FetchBookmarks(h.DB, userID)
+	page, err := repository.FetchBookmarks(r.Context(), h.DB, userID, repository.ListOptions{})
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(bms)
+	json.NewEncoder(w).Encode(page.Items)
 }
@@ -0,0 +1,121 @@
+// Package importer parses bookmark collections from the formats other
+// bookmark managers export, so they can be bulk-inserted via
+// repository.BulkCreateBookmarks.
+package importer
+
+import (
+	"Bookmark-RESTful/repository"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// ParseNetscape stream-parses the standard Netscape Bookmark File Format
+// (the <DL><DT><A HREF=...> tree exported by Firefox, Chrome and Shiori).
+// Folder names (<H3> headings) are carried onto each bookmark found beneath
+// them as tags.
+func ParseNetscape(r io.Reader) ([]repository.Bookmark, error) {
+	z := html.NewTokenizer(r)
+
+	var folders []string
+	var bookmarks []repository.Bookmark
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return nil, err
+			}
+			return bookmarks, nil
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if string(name) == "dl" && len(folders) > 0 {
+				folders = folders[:len(folders)-1]
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch string(name) {
+			case "a":
+				bm := repository.Bookmark{}
+				if hasAttr {
+					for {
+						key, val, more := z.TagAttr()
+						switch strings.ToLower(string(key)) {
+						case "href":
+							bm.URL = string(val)
+						case "add_date":
+							if secs, err := strconv.ParseInt(string(val), 10, 64); err == nil {
+								bm.CreatedAt = time.Unix(secs, 0).UTC()
+							}
+						}
+						if !more {
+							break
+						}
+					}
+				}
+				if z.Next() == html.TextToken {
+					bm.Title = strings.TrimSpace(string(z.Text()))
+				}
+				if len(folders) > 0 {
+					bm.Tags = append(bm.Tags, folders...)
+				}
+				if bm.URL != "" {
+					bookmarks = append(bookmarks, bm)
+				}
+
+			case "h3":
+				if z.Next() == html.TextToken {
+					folders = append(folders, strings.TrimSpace(string(z.Text())))
+				}
+			}
+		}
+	}
+}
+
+// ParseJSON decodes a plain JSON array of bookmarks, as produced by GET
+// /bookmarks/export?format=json.
+func ParseJSON(r io.Reader) ([]repository.Bookmark, error) {
+	var bookmarks []repository.Bookmark
+	if err := json.NewDecoder(r).Decode(&bookmarks); err != nil {
+		return nil, err
+	}
+	return bookmarks, nil
+}
+
+// WriteNetscape renders bookmarks as a Netscape Bookmark File Format document.
+func WriteNetscape(w io.Writer, bookmarks []*repository.Bookmark) error {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE NETSCAPE-Bookmark-file-1>\n")
+	b.WriteString(`<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">` + "\n")
+	b.WriteString("<TITLE>Bookmarks</TITLE>\n<H1>Bookmarks</H1>\n<DL><p>\n")
+	for _, bm := range bookmarks {
+		b.WriteString("    <DT><A HREF=\"")
+		b.WriteString(escapeAttr(bm.URL))
+		b.WriteString("\" ADD_DATE=\"")
+		b.WriteString(strconv.FormatInt(bm.CreatedAt.Unix(), 10))
+		b.WriteString("\">")
+		b.WriteString(escapeText(bm.Title))
+		b.WriteString("</A></DT>\n")
+	}
+	b.WriteString("</DL><p>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func escapeAttr(s string) string {
+	s = strings.ReplaceAll(s, "&", "&amp;")
+	return strings.ReplaceAll(s, "\"", "&quot;")
+}
+
+func escapeText(s string) string {
+	s = strings.ReplaceAll(s, "&", "&amp;")
+	s = strings.ReplaceAll(s, "<", "&lt;")
+	return strings.ReplaceAll(s, ">", "&gt;")
+}
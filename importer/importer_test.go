@@ -0,0 +1,45 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseNetscapeMapsFoldersToTags(t *testing.T) {
+	const doc = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<DL><p>
+    <DT><H3>Reading</H3>
+    <DL><p>
+        <DT><A HREF="https://example.com/a" ADD_DATE="1700000000">Article A</A>
+        <DT><H3>Go</H3>
+        <DL><p>
+            <DT><A HREF="https://example.com/b" ADD_DATE="1700000100">Article B</A>
+        </DL><p>
+    </DL><p>
+    <DT><A HREF="https://example.com/c" ADD_DATE="1700000200">Article C</A>
+</DL><p>
+`
+
+	bookmarks, err := ParseNetscape(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("ParseNetscape returned error: %v", err)
+	}
+	if len(bookmarks) != 3 {
+		t.Fatalf("got %d bookmarks, want 3", len(bookmarks))
+	}
+
+	byURL := make(map[string][]string, len(bookmarks))
+	for _, bm := range bookmarks {
+		byURL[bm.URL] = bm.Tags
+	}
+
+	if tags := byURL["https://example.com/a"]; len(tags) != 1 || tags[0] != "Reading" {
+		t.Errorf("tags for /a = %v, want [Reading]", tags)
+	}
+	if tags := byURL["https://example.com/b"]; len(tags) != 2 || tags[0] != "Reading" || tags[1] != "Go" {
+		t.Errorf("tags for /b = %v, want [Reading Go]", tags)
+	}
+	if tags := byURL["https://example.com/c"]; len(tags) != 0 {
+		t.Errorf("tags for /c = %v, want none", tags)
+	}
+}
@@ -1,18 +1,25 @@
 package main
 
 import (
+	"Bookmark-RESTful/archive"
+	"Bookmark-RESTful/auth"
 	"Bookmark-RESTful/db"
 	"Bookmark-RESTful/handler"
-	"Bookmark-RESTful/repository"
+	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/joho/godotenv"
 )
 
+// archiveConcurrency bounds how many pages are fetched/sanitized at once.
+const archiveConcurrency = 4
+
 func main() {
 	err := godotenv.Load()
 	if err != nil {
@@ -26,15 +33,30 @@ func main() {
 	defer database.Close()
 	fmt.Println("Successfully connected to the database!")
 
-	// Generate API keys for existing users who don't have one
-	if err := repository.UpdateExistingUsersWithAPIKey(database); err != nil {
-		log.Printf("Warning: Could not update existing users with API keys: %v\n", err)
-	} else {
-		log.Println("Successfully updated existing users with API keys.")
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(database, os.Args[2:])
+		return
+	}
+
+	if err := db.Migrate(context.Background(), database); err != nil {
+		log.Fatalf("Could not run migrations: %v", err)
+	}
+
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		log.Fatal("JWT_SECRET is not set")
 	}
 
 	// Initialize the handler with the database connection
-	h := &handler.Handler{DB: database}
+	archiveDir := os.Getenv("ARCHIVE_DIR")
+	if archiveDir == "" {
+		archiveDir = "./archives"
+	}
+	h := &handler.Handler{
+		DB:            database,
+		Auth:          auth.NewTokenManager(jwtSecret),
+		ArchiveWorker: archive.NewWorker(database, archiveDir, archiveConcurrency),
+	}
 
 	// Create a new chi router
 	r := chi.NewRouter()
@@ -51,19 +73,55 @@ func main() {
 		})
 	})
 
-	// Protected routes (API key required)
+	// Auth routes: login/refresh are public, key management requires a session.
+	r.Route("/auth", func(r chi.Router) {
+		r.Post("/login", h.Login)
+		r.Post("/refresh", h.Refresh)
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.Authenticate)
+
+			r.Route("/keys", func(r chi.Router) {
+				r.Post("/", h.CreateAPIKey)
+				r.Get("/", h.ListAPIKeys)
+				r.Delete("/{id}", h.DeleteAPIKey)
+			})
+		})
+	})
+
+	// Protected routes (JWT access token or API key required)
 	r.Route("/", func(r chi.Router) {
-		// apply the API key middleware to all routes in this group
-		r.Use(h.APIKeyMiddleware)
+		r.Use(h.Authenticate)
+
+		read := auth.RequireScope(auth.ScopeReadBookmarks)
+		write := auth.RequireScope(auth.ScopeWriteBookmarks)
 
 		r.Route("/bookmarks", func(r chi.Router) {
-			r.Post("/", h.CreateBookmark)
-			r.Get("/", h.ListBookmarksForCurrentUser)
+			r.With(write).Post("/", h.CreateBookmark)
+			r.With(read).Get("/", h.ListBookmarksForCurrentUser)
+			r.With(write).Post("/import", h.ImportBookmarks)
+			r.With(read).Get("/export", h.ExportBookmarks)
+
+			r.Route("/{id}/tags", func(r chi.Router) {
+				r.With(write).Post("/", h.AddBookmarkTags)
+				r.With(write).Delete("/{tag}", h.RemoveBookmarkTag)
+			})
+
+			r.Route("/{id}/comments", func(r chi.Router) {
+				r.With(write).Post("/", h.AddComment)
+				r.With(read).Get("/", h.ListComments)
+			})
+
+			r.With(write).Post("/{id}/archive", h.ArchiveBookmark)
+			r.With(read).Get("/{id}/content", h.GetBookmarkContent)
 		})
 
-		r.Route("/auth", func(r chi.Router) {
-			r.Post("/regenerate-key", h.RegenerateAPIKey)
+		r.Route("/comments/{id}", func(r chi.Router) {
+			r.With(write).Patch("/", h.UpdateComment)
+			r.With(write).Delete("/", h.DeleteComment)
 		})
+
+		r.With(read).Get("/tags", h.ListTags)
 	})
 
 	// Start the server
@@ -73,3 +131,40 @@ func main() {
 		log.Fatalf("Could not start server: %v", err)
 	}
 }
+
+// runMigrateCommand implements "bookmark-restful migrate up|down|status",
+// letting operators inspect and roll back schema changes without starting
+// the HTTP server.
+func runMigrateCommand(database *sql.DB, args []string) {
+	if len(args) == 0 {
+		log.Fatal("Usage: bookmark-restful migrate up|down|status")
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		if err := db.Migrate(ctx, database); err != nil {
+			log.Fatalf("Could not apply migrations: %v", err)
+		}
+		fmt.Println("Migrations applied.")
+	case "down":
+		if err := db.Rollback(ctx, database); err != nil {
+			log.Fatalf("Could not roll back migration: %v", err)
+		}
+		fmt.Println("Last migration rolled back.")
+	case "status":
+		statuses, err := db.MigrationStatus(ctx, database)
+		if err != nil {
+			log.Fatalf("Could not read migration status: %v", err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied"
+			}
+			fmt.Printf("%04d_%s: %s\n", s.Version, s.Name, state)
+		}
+	default:
+		log.Fatalf("Unknown migrate subcommand %q", args[0])
+	}
+}
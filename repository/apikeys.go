@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIKey is a named, scoped credential a user can issue for
+// machine-to-machine access, as an alternative to a JWT access token.
+type APIKey struct {
+	ID        int        `json:"id"`
+	UserID    int        `json:"user_id"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// ErrAPIKeyNotFound signals that an API key does not exist for the given user.
+var ErrAPIKeyNotFound = errors.New("API key not found")
+
+// CreateAPIKey issues a new named API key for userID with the given scopes
+// and optional expiration, returning the key's metadata and the plaintext
+// secret. The secret is only ever available at creation time; only its hash
+// is persisted.
+func CreateAPIKey(ctx context.Context, db *sql.DB, userID int, name string, scopes []string, expiresAt *time.Time) (*APIKey, string, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, "", fmt.Errorf("could not generate API key: %w", err)
+	}
+
+	res, err := db.ExecContext(ctx,
+		"INSERT INTO api_keys (user_id, name, key_hash, scopes, expires_at) VALUES (?, ?, ?, ?, ?)",
+		userID, name, hashSecret(secret), strings.Join(scopes, ","), expiresAt,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &APIKey{
+		ID:        int(id),
+		UserID:    userID,
+		Name:      name,
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+	return key, secret, nil
+}
+
+// ListAPIKeys returns all API keys belonging to a user, newest first.
+func ListAPIKeys(ctx context.Context, db *sql.DB, userID int) ([]*APIKey, error) {
+	rows, err := db.QueryContext(ctx,
+		"SELECT id, name, scopes, created_at, expires_at FROM api_keys WHERE user_id = ? ORDER BY created_at DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*APIKey
+	for rows.Next() {
+		key := &APIKey{UserID: userID}
+		var scopes string
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&key.ID, &key.Name, &scopes, &key.CreatedAt, &expiresAt); err != nil {
+			return nil, err
+		}
+		key.Scopes = splitScopes(scopes)
+		if expiresAt.Valid {
+			key.ExpiresAt = &expiresAt.Time
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// DeleteAPIKey revokes an API key owned by userID.
+func DeleteAPIKey(ctx context.Context, db *sql.DB, userID, keyID int) error {
+	res, err := db.ExecContext(ctx, "DELETE FROM api_keys WHERE id = ? AND user_id = ?", keyID, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// GetUserByAPIKeySecret looks up the user and scopes for a plaintext API key,
+// rejecting keys that don't exist or have expired.
+func GetUserByAPIKeySecret(ctx context.Context, db *sql.DB, secret string) (*User, []string, error) {
+	var user User
+	var scopes string
+	var expiresAt sql.NullTime
+
+	err := db.QueryRowContext(ctx,
+		`SELECT u.id, u.name, u.email, k.scopes, k.expires_at
+		 FROM api_keys k JOIN users u ON u.id = k.user_id
+		 WHERE k.key_hash = ?`,
+		hashSecret(secret),
+	).Scan(&user.ID, &user.Name, &user.Email, &scopes, &expiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, ErrInvalidAPIKey
+		}
+		return nil, nil, err
+	}
+
+	if expiresAt.Valid && expiresAt.Time.Before(time.Now()) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	return &user, splitScopes(scopes), nil
+}
+
+func splitScopes(scopes string) []string {
+	if scopes == "" {
+		return nil
+	}
+	return strings.Split(scopes, ",")
+}
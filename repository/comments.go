@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Comment is a free-text note a user has attached to one of their bookmarks.
+type Comment struct {
+	ID         int       `json:"id"`
+	BookmarkID int       `json:"bookmark_id"`
+	UserID     int       `json:"user_id"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ErrCommentNotFound signals that a comment does not exist for the given user.
+var ErrCommentNotFound = errors.New("comment not found")
+
+// AddComment attaches a new comment to a bookmark owned by userID.
+func AddComment(ctx context.Context, db *sql.DB, userID, bookmarkID int, body string) (*Comment, error) {
+	if err := checkBookmarkOwnership(ctx, db, userID, bookmarkID); err != nil {
+		return nil, err
+	}
+
+	res, err := db.ExecContext(ctx,
+		"INSERT INTO comments (bookmark_id, user_id, body) VALUES (?, ?, ?)",
+		bookmarkID, userID, body,
+	)
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &Comment{ID: int(id), BookmarkID: bookmarkID, UserID: userID, Body: body}
+	err = db.QueryRowContext(ctx, "SELECT created_at, updated_at FROM comments WHERE id = ?", id).
+		Scan(&comment.CreatedAt, &comment.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// ListComments returns the comments on a bookmark owned by userID, oldest first.
+func ListComments(ctx context.Context, db *sql.DB, userID, bookmarkID, limit, offset int) ([]*Comment, error) {
+	if err := checkBookmarkOwnership(ctx, db, userID, bookmarkID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, user_id, body, created_at, updated_at FROM comments
+		WHERE bookmark_id = ? ORDER BY created_at ASC`
+	args := []interface{}{bookmarkID}
+	if limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, offset)
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []*Comment
+	for rows.Next() {
+		c := &Comment{BookmarkID: bookmarkID}
+		if err := rows.Scan(&c.ID, &c.UserID, &c.Body, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+// UpdateComment updates the body of a comment owned by userID.
+func UpdateComment(ctx context.Context, db *sql.DB, userID, commentID int, body string) error {
+	res, err := db.ExecContext(ctx,
+		"UPDATE comments SET body = ?, updated_at = NOW() WHERE id = ? AND user_id = ?",
+		body, commentID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
+// DeleteComment deletes a comment owned by userID.
+func DeleteComment(ctx context.Context, db *sql.DB, userID, commentID int) error {
+	res, err := db.ExecContext(ctx, "DELETE FROM comments WHERE id = ? AND user_id = ?", commentID, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
+// deleteCommentsForBookmark deletes all comments on a bookmark within an
+// already-open transaction, e.g. when the bookmark itself is deleted.
+func deleteCommentsForBookmark(ctx context.Context, tx *sql.Tx, bookmarkID int) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM comments WHERE bookmark_id = ?", bookmarkID)
+	return err
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEncodeDecodeCursorRoundTrip(t *testing.T) {
+	id := 42
+	createdAt := time.Unix(0, 1700000000123456789)
+
+	cursor := encodeCursor(id, createdAt)
+
+	gotID, gotCreatedAt, err := decodeCursor(cursor)
+	if err != nil {
+		t.Fatalf("decodeCursor(%q) returned error: %v", cursor, err)
+	}
+	if gotID != id {
+		t.Errorf("decodeCursor id = %d, want %d", gotID, id)
+	}
+	if !gotCreatedAt.Equal(createdAt) {
+		t.Errorf("decodeCursor createdAt = %v, want %v", gotCreatedAt, createdAt)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	if _, _, err := decodeCursor("not-a-cursor"); err == nil {
+		t.Error("decodeCursor(\"not-a-cursor\") returned nil error, want non-nil")
+	}
+}
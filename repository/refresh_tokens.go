@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// refreshTokenTTL is how long a refresh token remains valid after issuance.
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+// ErrInvalidRefreshToken signals that a refresh token is unknown, expired, or revoked.
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+// CreateRefreshToken issues a new long-lived refresh token for a user,
+// returning the plaintext token. Only its hash is persisted.
+func CreateRefreshToken(ctx context.Context, db *sql.DB, userID int) (string, error) {
+	token, err := generateSecret()
+	if err != nil {
+		return "", fmt.Errorf("could not generate refresh token: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx,
+		"INSERT INTO refresh_tokens (user_id, token_hash, expires_at) VALUES (?, ?, ?)",
+		userID, hashSecret(token), time.Now().Add(refreshTokenTTL),
+	)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// GetUserByRefreshToken resolves a plaintext refresh token to its owning
+// user, rejecting tokens that are unknown, expired, or already revoked.
+func GetUserByRefreshToken(ctx context.Context, db *sql.DB, token string) (*User, error) {
+	var user User
+	var expiresAt time.Time
+
+	err := db.QueryRowContext(ctx,
+		`SELECT u.id, u.name, u.email, rt.expires_at
+		 FROM refresh_tokens rt JOIN users u ON u.id = rt.user_id
+		 WHERE rt.token_hash = ? AND rt.revoked_at IS NULL`,
+		hashSecret(token),
+	).Scan(&user.ID, &user.Name, &user.Email, &expiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if expiresAt.Before(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return &user, nil
+}
+
+// RevokeRefreshToken marks a refresh token as no longer usable, e.g. after
+// it has been exchanged for a new one.
+func RevokeRefreshToken(ctx context.Context, db *sql.DB, token string) error {
+	_, err := db.ExecContext(ctx,
+		"UPDATE refresh_tokens SET revoked_at = NOW() WHERE token_hash = ?",
+		hashSecret(token),
+	)
+	return err
+}
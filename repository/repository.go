@@ -2,36 +2,54 @@ package repository
 
 import (
 	"bufio"
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"strings"
 	"time"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 type User struct {
-	ID     int    `json:"id"`
-	Name   string `json:"name"`
-	Email  string `json:"email"`
-	APIKey string `json:"api_key,omitempty"`
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	PasswordHash string `json:"-"`
 }
 
 type Bookmark struct {
-	ID        int       `json:"id"`
-	UserID    int       `json:"user_id"`
-	Title     string    `json:"title"`
-	URL       string    `json:"url"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          int       `json:"id"`
+	UserID      int       `json:"user_id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	CreatedAt   time.Time `json:"created_at"`
+	Tags        []string  `json:"tags,omitempty"`
+	Archived    bool      `json:"archived"`
+	ArchivePath string    `json:"archive_path,omitempty"`
+	Excerpt     string    `json:"excerpt,omitempty"`
+	ImageURL    string    `json:"image_url,omitempty"`
 }
 
 // ErrEmailTaken signals that the email is already taken.
 var ErrEmailTaken = errors.New("email already in use")
+
+// ErrInvalidAPIKey signals that an API key does not match any active key.
 var ErrInvalidAPIKey = errors.New("invalid API key")
 
-// generateAPIKey creates a random 32-bytehex string
-func generateAPIKey() (string, error) {
+// ErrBookmarkNotFound signals that a bookmark does not exist for the given user.
+var ErrBookmarkNotFound = errors.New("bookmark not found")
+
+// ErrInvalidCursor signals that a caller-supplied pagination cursor could not be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// generateSecret creates a random 32-byte hex string, used as the raw value
+// behind both API keys and refresh tokens.
+func generateSecret() (string, error) {
 	bytes := make([]byte, 32)
 	if _, err := rand.Read(bytes); err != nil {
 		return "", err
@@ -39,13 +57,21 @@ func generateAPIKey() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
-// CreateUser inserts a new user, returning ErrEmailTaken if the email is already taken.
-func CreateUser(db *sql.DB, name, email string) (*User, error) {
+// hashSecret hashes a raw secret (API key or refresh token) for storage, so
+// the plaintext value only ever exists in the response that issued it.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateUser inserts a new user with a bcrypt-hashed password, returning
+// ErrEmailTaken if the email is already taken.
+func CreateUser(ctx context.Context, db *sql.DB, name, email, password string) (*User, error) {
 	// Check uniqueness
 	var exists bool
 
-	// QueryRow executes a query expected to return at most one row.
-	row := db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE email = ?)", email)
+	// QueryRowContext executes a query expected to return at most one row.
+	row := db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE email = ?)", email)
 
 	// Scan copies the columns from the matched row into the values pointed to by its arguments.
 	if err := row.Scan(&exists); err != nil {
@@ -56,14 +82,12 @@ func CreateUser(db *sql.DB, name, email string) (*User, error) {
 		return nil, ErrEmailTaken
 	}
 
-	// Generate API key
-	apiKey, err := generateAPIKey()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return nil, fmt.Errorf("could not generate API key: %w", err)
+		return nil, fmt.Errorf("could not hash password: %w", err)
 	}
 
-	// Insert user with API key
-	res, err := db.Exec("INSERT INTO users (name, email, api_key) VALUES (?, ?, ?)", name, email, apiKey)
+	res, err := db.ExecContext(ctx, "INSERT INTO users (name, email, password_hash) VALUES (?, ?, ?)", name, email, string(hash))
 	if err != nil {
 		return nil, err
 	}
@@ -73,39 +97,12 @@ func CreateUser(db *sql.DB, name, email string) (*User, error) {
 		return nil, err
 	}
 
-	return &User{ID: int(id), Name: name, Email: email, APIKey: apiKey}, nil
-}
-
-// GetUserByAPIKey retrieves a user by their API key.
-func GetUserByAPIKey(db *sql.DB, apiKey string) (*User, error) {
-	user := &User{}
-	err := db.QueryRow("SELECT id, name, email, api_key FROM users WHERE api_key = ?", apiKey).Scan(&user.ID, &user.Name, &user.Email, &user.APIKey)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, ErrInvalidAPIKey
-		}
-		return nil, err
-	}
-	return user, nil
-}
-
-// RegenerateAPIKey generates a new API key for a user.
-func RegenerateAPIKey(db *sql.DB, userID int) (string, error) {
-	newAPIKey, err := generateAPIKey()
-	if err != nil {
-		return "", fmt.Errorf("could not generate new API key: %w", err)
-	}
-
-	_, err = db.Exec("UPDATE users SET api_key = ? WHERE id = ?", newAPIKey, userID)
-	if err != nil {
-		return "", fmt.Errorf("could not update API key: %w", err)
-	}
-	return newAPIKey, nil
+	return &User{ID: int(id), Name: name, Email: email}, nil
 }
 
 // CreateBookmark inserts a new bookmark for a given user.
-func CreateBookmark(db *sql.DB, userID int, title, url string) (*Bookmark, error) {
-	res, err := db.Exec(
+func CreateBookmark(ctx context.Context, db *sql.DB, userID int, title, url string) (*Bookmark, error) {
+	res, err := db.ExecContext(ctx,
 		"INSERT INTO bookmarks (user_id, title, url) VALUES (?, ?, ?)", userID, title, url,
 	)
 	if err != nil {
@@ -123,7 +120,7 @@ func CreateBookmark(db *sql.DB, userID int, title, url string) (*Bookmark, error
 	}
 
 	// fetch created_at
-	err = db.QueryRow("SELECT created_at FROM bookmarks WHERE id = ?", id).Scan(&bm.CreatedAt)
+	err = db.QueryRowContext(ctx, "SELECT created_at FROM bookmarks WHERE id = ?", id).Scan(&bm.CreatedAt)
 	if err != nil {
 		// if we can't get the timestamp, it's better to return the error
 		// than a partially populated object.
@@ -132,6 +129,108 @@ func CreateBookmark(db *sql.DB, userID int, title, url string) (*Bookmark, error
 	return bm, nil
 }
 
+// GetBookmark retrieves a single bookmark owned by userID, including its
+// archive state. It returns ErrBookmarkNotFound if no such bookmark exists.
+func GetBookmark(ctx context.Context, db *sql.DB, userID, bookmarkID int) (*Bookmark, error) {
+	bm := &Bookmark{UserID: userID}
+	var archivePath, excerpt, imageURL sql.NullString
+
+	err := db.QueryRowContext(ctx,
+		`SELECT id, title, url, created_at, archived, archive_path, excerpt, image_url
+		 FROM bookmarks WHERE id = ? AND user_id = ?`,
+		bookmarkID, userID,
+	).Scan(&bm.ID, &bm.Title, &bm.URL, &bm.CreatedAt, &bm.Archived, &archivePath, &excerpt, &imageURL)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrBookmarkNotFound
+		}
+		return nil, err
+	}
+
+	bm.ArchivePath = archivePath.String
+	bm.Excerpt = excerpt.String
+	bm.ImageURL = imageURL.String
+	return bm, nil
+}
+
+// UpdateBookmarkArchive records the result of archiving a bookmark's page.
+func UpdateBookmarkArchive(ctx context.Context, db *sql.DB, bookmarkID int, archivePath, excerpt, imageURL string) error {
+	_, err := db.ExecContext(ctx,
+		"UPDATE bookmarks SET archived = TRUE, archive_path = ?, excerpt = ?, image_url = ? WHERE id = ?",
+		archivePath, excerpt, imageURL, bookmarkID,
+	)
+	return err
+}
+
+// BulkCreateBookmarks inserts many bookmarks for a user in a single transaction,
+// deduplicating against both the existing table and the incoming batch by URL.
+// Bookmarks carrying tags (e.g. from a Netscape folder import) have those tags
+// attached as part of the same transaction.
+func BulkCreateBookmarks(ctx context.Context, db *sql.DB, userID int, bookmarks []Bookmark) (inserted, skipped int, err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not start the transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	checkStmt, err := tx.PrepareContext(ctx, "SELECT EXISTS(SELECT 1 FROM bookmarks WHERE user_id = ? AND url = ?)")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer checkStmt.Close()
+
+	insertStmt, err := tx.PrepareContext(ctx, "INSERT INTO bookmarks (user_id, title, url, created_at) VALUES (?, ?, ?, ?)")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer insertStmt.Close()
+
+	seen := make(map[string]bool, len(bookmarks))
+	for _, bm := range bookmarks {
+		if bm.URL == "" || seen[bm.URL] {
+			skipped++
+			continue
+		}
+
+		var exists bool
+		if err := checkStmt.QueryRowContext(ctx, userID, bm.URL).Scan(&exists); err != nil {
+			return inserted, skipped, err
+		}
+		if exists {
+			skipped++
+			continue
+		}
+		seen[bm.URL] = true
+
+		createdAt := bm.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+
+		res, err := insertStmt.ExecContext(ctx, userID, bm.Title, bm.URL, createdAt)
+		if err != nil {
+			return inserted, skipped, err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return inserted, skipped, err
+		}
+
+		if len(bm.Tags) > 0 {
+			if err := addTagsTx(ctx, tx, userID, int(id), bm.Tags); err != nil {
+				return inserted, skipped, err
+			}
+		}
+
+		inserted++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return inserted, skipped, err
+	}
+	return inserted, skipped, nil
+}
+
 // Helper func to add a bookmark.
 func AddBookmark(db *sql.DB, reader *bufio.Reader, userID int) {
 	fmt.Print("Title: ")
@@ -146,7 +245,7 @@ func AddBookmark(db *sql.DB, reader *bufio.Reader, userID int) {
 	url, _ := reader.ReadString('\n')
 	url = strings.TrimSpace(url)
 
-	bm, err := CreateBookmark(db, userID, title, url)
+	bm, err := CreateBookmark(context.Background(), db, userID, title, url)
 	if err != nil {
 		fmt.Println("could not save bookmark: ", err)
 		return
@@ -154,9 +253,288 @@ func AddBookmark(db *sql.DB, reader *bufio.Reader, userID int) {
 	fmt.Printf("Saved: %s\n", bm.Title)
 }
 
-// FetchBookmarks retrieves all bookmarks for a user
-func FetchBookmarks(db *sql.DB, userID int) ([]*Bookmark, error) {
-	rows, err := db.Query("SELECT id, title, url, created_at FROM bookmarks WHERE user_id = ?", userID)
+// ListOptions controls keyset pagination for FetchBookmarks.
+type ListOptions struct {
+	// Limit caps the number of items returned; 0 uses a sensible default.
+	Limit int
+	// Cursor, if set, resumes listing after the bookmark it encodes.
+	Cursor string
+	// Order is "asc" or "desc" (the default) by created_at.
+	Order string
+}
+
+const defaultListLimit = 20
+
+// Page is a single page of keyset-paginated results.
+type Page[T any] struct {
+	Items      []*T   `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// FetchBookmarks retrieves a page of a user's bookmarks ordered by created_at,
+// using keyset pagination so deep pages remain cheap as the table grows.
+func FetchBookmarks(ctx context.Context, db *sql.DB, userID int, opts ListOptions) (*Page[Bookmark], error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+
+	order := "DESC"
+	if strings.EqualFold(opts.Order, "asc") {
+		order = "ASC"
+	}
+
+	query := "SELECT id, title, url, created_at FROM bookmarks WHERE user_id = ?"
+	args := []interface{}{userID}
+
+	if opts.Cursor != "" {
+		cursorID, cursorCreatedAt, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+		if order == "DESC" {
+			query += " AND (created_at, id) < (?, ?)"
+		} else {
+			query += " AND (created_at, id) > (?, ?)"
+		}
+		args = append(args, cursorCreatedAt, cursorID)
+	}
+
+	query += fmt.Sprintf(" ORDER BY created_at %s, id %s LIMIT ?", order, order)
+	args = append(args, limit+1)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*Bookmark
+	for rows.Next() {
+		bm := &Bookmark{UserID: userID}
+		if err := rows.Scan(&bm.ID, &bm.Title, &bm.URL, &bm.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, bm)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &Page[Bookmark]{}
+	if len(list) > limit {
+		list = list[:limit]
+		last := list[len(list)-1]
+		page.NextCursor = encodeCursor(last.ID, last.CreatedAt)
+	}
+	page.Items = list
+
+	if err := attachTags(ctx, db, list); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// encodeCursor and decodeCursor translate a keyset position to/from an
+// opaque string suitable for a query parameter.
+func encodeCursor(id int, createdAt time.Time) string {
+	return fmt.Sprintf("%d_%d", createdAt.UnixNano(), id)
+}
+
+func decodeCursor(cursor string) (id int, createdAt time.Time, err error) {
+	var nanos int64
+	if _, err := fmt.Sscanf(cursor, "%d_%d", &nanos, &id); err != nil {
+		return 0, time.Time{}, err
+	}
+	return id, time.Unix(0, nanos), nil
+}
+
+// attachTags populates the Tags field of each bookmark with a single query.
+func attachTags(ctx context.Context, db *sql.DB, bookmarks []*Bookmark) error {
+	if len(bookmarks) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*Bookmark, len(bookmarks))
+	placeholders := make([]string, len(bookmarks))
+	args := make([]interface{}, len(bookmarks))
+	for i, bm := range bookmarks {
+		byID[bm.ID] = bm
+		placeholders[i] = "?"
+		args[i] = bm.ID
+	}
+
+	query := fmt.Sprintf(
+		`SELECT bt.bookmark_id, t.name FROM bookmark_tags bt
+		 JOIN tags t ON t.id = bt.tag_id
+		 WHERE bt.bookmark_id IN (%s)
+		 ORDER BY t.name`,
+		strings.Join(placeholders, ","),
+	)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bookmarkID int
+		var tag string
+		if err := rows.Scan(&bookmarkID, &tag); err != nil {
+			return err
+		}
+		if bm, ok := byID[bookmarkID]; ok {
+			bm.Tags = append(bm.Tags, tag)
+		}
+	}
+	return rows.Err()
+}
+
+// AddTagsToBookmark attaches the given tags to a bookmark owned by userID,
+// creating any tags that don't already exist for that user.
+func AddTagsToBookmark(ctx context.Context, db *sql.DB, userID, bookmarkID int, tags []string) error {
+	if err := checkBookmarkOwnership(ctx, db, userID, bookmarkID); err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not start the transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := addTagsTx(ctx, tx, userID, bookmarkID, tags); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// addTagsTx attaches tags to a bookmark within an already-open transaction,
+// creating any tags that don't already exist for the user.
+func addTagsTx(ctx context.Context, tx *sql.Tx, userID, bookmarkID int, tags []string) error {
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+
+		tagID, err := getOrCreateTag(ctx, tx, userID, tag)
+		if err != nil {
+			return fmt.Errorf("could not create tag %q: %w", tag, err)
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"INSERT IGNORE INTO bookmark_tags (bookmark_id, tag_id) VALUES (?, ?)",
+			bookmarkID, tagID,
+		)
+		if err != nil {
+			return fmt.Errorf("could not attach tag %q: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// getOrCreateTag returns the id of the user's tag with the given name, creating it if necessary.
+func getOrCreateTag(ctx context.Context, tx *sql.Tx, userID int, name string) (int, error) {
+	var id int
+	err := tx.QueryRowContext(ctx, "SELECT id FROM tags WHERE user_id = ? AND name = ?", userID, name).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, "INSERT INTO tags (user_id, name) VALUES (?, ?)", userID, name)
+	if err != nil {
+		return 0, err
+	}
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(lastID), nil
+}
+
+// RemoveTagsFromBookmark detaches a tag from a bookmark owned by userID.
+func RemoveTagsFromBookmark(ctx context.Context, db *sql.DB, userID, bookmarkID int, tag string) error {
+	if err := checkBookmarkOwnership(ctx, db, userID, bookmarkID); err != nil {
+		return err
+	}
+
+	_, err := db.ExecContext(ctx,
+		`DELETE bt FROM bookmark_tags bt
+		 JOIN tags t ON t.id = bt.tag_id
+		 WHERE bt.bookmark_id = ? AND t.user_id = ? AND t.name = ?`,
+		bookmarkID, userID, tag,
+	)
+	return err
+}
+
+// checkBookmarkOwnership returns ErrBookmarkNotFound if bookmarkID doesn't belong to userID.
+func checkBookmarkOwnership(ctx context.Context, db *sql.DB, userID, bookmarkID int) error {
+	var exists bool
+	err := db.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM bookmarks WHERE id = ? AND user_id = ?)", bookmarkID, userID,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrBookmarkNotFound
+	}
+	return nil
+}
+
+// ListTags returns the distinct tag names a user has created, sorted alphabetically.
+func ListTags(ctx context.Context, db *sql.DB, userID int) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM tags WHERE user_id = ? ORDER BY name", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// SearchBookmarks returns a user's bookmarks filtered by a free-text query over
+// title/url and an intersection of tags, with limit/offset pagination.
+func SearchBookmarks(ctx context.Context, db *sql.DB, userID int, query string, tags []string, limit, offset int) ([]*Bookmark, error) {
+	args := []interface{}{userID}
+	sqlQuery := `SELECT b.id, b.title, b.url, b.created_at FROM bookmarks b WHERE b.user_id = ?`
+
+	if query != "" {
+		sqlQuery += " AND MATCH(b.title, b.url) AGAINST (? IN NATURAL LANGUAGE MODE)"
+		args = append(args, query)
+	}
+
+	for _, tag := range tags {
+		sqlQuery += ` AND EXISTS (
+			SELECT 1 FROM bookmark_tags bt
+			JOIN tags t ON t.id = bt.tag_id
+			WHERE bt.bookmark_id = b.id AND t.user_id = ? AND t.name = ?
+		)`
+		args = append(args, userID, tag)
+	}
+
+	sqlQuery += " ORDER BY b.created_at DESC"
+
+	if limit > 0 {
+		sqlQuery += " LIMIT ? OFFSET ?"
+		args = append(args, limit, offset)
+	}
+
+	rows, err := db.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -164,80 +542,60 @@ func FetchBookmarks(db *sql.DB, userID int) ([]*Bookmark, error) {
 
 	var list []*Bookmark
 	for rows.Next() {
-		var bm Bookmark
-		bm.UserID = userID
+		bm := &Bookmark{UserID: userID}
 		if err := rows.Scan(&bm.ID, &bm.Title, &bm.URL, &bm.CreatedAt); err != nil {
 			return nil, err
 		}
-		list = append(list, &bm)
+		list = append(list, bm)
+	}
+	if err := attachTags(ctx, db, list); err != nil {
+		return nil, err
 	}
 	return list, nil
 }
 
 // Helper func to list bookmarks.
 func ListBookmarks(db *sql.DB, userID int) {
-	bms, err := FetchBookmarks(db, userID)
+	page, err := FetchBookmarks(context.Background(), db, userID, ListOptions{})
 	if err != nil {
 		fmt.Println("could not retrieve bookmarks:", err)
 		return
 	}
 
-	if len(bms) == 0 {
+	if len(page.Items) == 0 {
 		fmt.Println("Empty. You haven't added any bookmarks yet.")
 		return
 	}
 
 	fmt.Println("\n--- Your bookmarks ---")
-	for _, bm := range bms {
+	for _, bm := range page.Items {
 		fmt.Printf("\nTitle: %s\nURL: %s\nCreated At: %s\n", bm.Title, bm.URL, bm.CreatedAt.Format(time.RFC3339))
 	}
 }
 
-// GetUserByEmail retrieves a user by their email address.
-// It returns sql.ErrNoRows if no user is found.
-func GetUserByEmail(db *sql.DB, email string) (*User, error) {
+// GetUserByEmail retrieves a user, including their password hash for
+// credential verification, by their email address. It returns sql.ErrNoRows
+// if no user is found.
+func GetUserByEmail(ctx context.Context, db *sql.DB, email string) (*User, error) {
 	user := &User{Email: email}
 
-	// since emails are unique, we only want one user, QueryRow is appropriate.
-	// db.Query for multiple rows.
-	err := db.QueryRow("SELECT id, name FROM users WHERE email = ?", email).Scan(&user.ID, &user.Name)
+	// since emails are unique, we only want one user, QueryRowContext is appropriate.
+	// db.QueryContext for multiple rows.
+	err := db.QueryRowContext(ctx, "SELECT id, name, password_hash FROM users WHERE email = ?", email).Scan(&user.ID, &user.Name, &user.PasswordHash)
 	if err != nil {
 		return nil, err // sql.ErrNoRows is returned if no user is found
 	}
 	return user, nil
 }
 
-// UpdateExistingUsersWithAPIKey generates API keys for users who don't have one.
-func UpdateExistingUsersWithAPIKey(db *sql.DB) error {
-	// Get all users without an API key
-	rows, err := db.Query("SELECT id FROM users WHERE api_key IS NULL")
+// GetUserByID retrieves a user by their numeric ID.
+func GetUserByID(ctx context.Context, db *sql.DB, userID int) (*User, error) {
+	user := &User{ID: userID}
+	err := db.QueryRowContext(ctx, "SELECT name, email FROM users WHERE id = ?", userID).Scan(&user.Name, &user.Email)
 	if err != nil {
-		return err
-	}
-	defer rows.Close()
-
-	var userIDs []int
-	for rows.Next() {
-		var userID int
-		if err := rows.Scan(&userID); err != nil {
-			return err
-		}
-		userIDs = append(userIDs, userID)
-	}
-
-	// Generate API keys for each user
-	for _, userID := range userIDs {
-		apiKey, err := generateAPIKey()
-		if err != nil {
-			return fmt.Errorf("could not generate API key for user %d: %w", userID, err)
-		}
-
-		_, err = db.Exec("UPDATE users SET api_key = ? WHERE id = ?", apiKey, userID)
-		if err != nil {
-			return fmt.Errorf("could not update API key for user %d: %w", userID, err)
-		}
+		return nil, err
 	}
-	return nil
+	return user, nil
 }
 
 // DeleteBookmark is a helper func to prompt for a title and delete the bookmark.
@@ -246,7 +604,7 @@ func DeleteBookmark(db *sql.DB, reader *bufio.Reader, userID int) {
 	title, _ := reader.ReadString('\n')
 	title = strings.TrimSpace(title)
 
-	rowsAffected, err := deleteBookmarkByTitle(db, userID, title)
+	rowsAffected, err := deleteBookmarkByTitle(context.Background(), db, userID, title)
 	if err != nil {
 		fmt.Printf("Could not delete bookmark: %v\n", err)
 		return
@@ -259,13 +617,44 @@ func DeleteBookmark(db *sql.DB, reader *bufio.Reader, userID int) {
 
 }
 
-// deleteBookmarkByTitle deletes a bookmark for a user given its title.
-func deleteBookmarkByTitle(db *sql.DB, userID int, title string) (int64, error) {
-	res, err := db.Exec("DELETE FROM bookmarks WHERE user_id = ? AND title = ?", userID, title)
+// deleteBookmarkByTitle deletes a bookmark for a user given its title, along
+// with any comments left on it.
+func deleteBookmarkByTitle(ctx context.Context, db *sql.DB, userID int, title string) (int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("could not start the transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var bookmarkID int
+	err = tx.QueryRowContext(ctx, "SELECT id FROM bookmarks WHERE user_id = ? AND title = ?", userID, title).Scan(&bookmarkID)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
 		return 0, err
 	}
-	return res.RowsAffected()
+
+	if err := deleteCommentsForBookmark(ctx, tx, bookmarkID); err != nil {
+		return 0, fmt.Errorf("could not delete comments: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM bookmark_tags WHERE bookmark_id = ?", bookmarkID); err != nil {
+		return 0, fmt.Errorf("could not delete bookmark tags: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM bookmarks WHERE id = ?", bookmarkID)
+	if err != nil {
+		return 0, err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return rowsAffected, nil
 }
 
 // DeleteAccount is a helper func to handle account deletion.
@@ -286,7 +675,7 @@ func DeleteAccount(db *sql.DB, reader *bufio.Reader, user *User) bool {
 			return false
 		}
 
-		err := deleteUserAndBookmarks(db, user.ID)
+		err := deleteUserAndBookmarks(context.Background(), db, user.ID)
 		if err != nil {
 			fmt.Printf("Failed to delete account: %v\n", err)
 			return false
@@ -299,9 +688,10 @@ func DeleteAccount(db *sql.DB, reader *bufio.Reader, user *User) bool {
 	return false
 }
 
-// deleteUserAndBookmarks deletes a user and all their bookmarks in a transaction.
-func deleteUserAndBookmarks(db *sql.DB, userID int) error {
-	tx, err := db.Begin()
+// deleteUserAndBookmarks deletes a user and all their bookmarks (and any
+// comments left on those bookmarks) in a transaction.
+func deleteUserAndBookmarks(ctx context.Context, db *sql.DB, userID int) error {
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("could not start the transaction: %w", err)
 	}
@@ -310,14 +700,33 @@ func deleteUserAndBookmarks(db *sql.DB, userID int) error {
 	// it will be ignored if the transaction is committed.
 	defer tx.Rollback()
 
-	// Delete bookmarks first
-	_, err = tx.Exec("DELETE FROM bookmarks WHERE user_id = ?", userID)
+	// Delete comments on the user's bookmarks first, then the bookmarks themselves.
+	_, err = tx.ExecContext(ctx,
+		"DELETE c FROM comments c JOIN bookmarks b ON b.id = c.bookmark_id WHERE b.user_id = ?", userID,
+	)
+	if err != nil {
+		return fmt.Errorf("could not delete comments: %w", err)
+	}
+
+	// Clear the rows that reference the bookmarks or the user by foreign key.
+	for _, stmt := range []string{
+		"DELETE bt FROM bookmark_tags bt JOIN bookmarks b ON b.id = bt.bookmark_id WHERE b.user_id = ?",
+		"DELETE FROM tags WHERE user_id = ?",
+		"DELETE FROM api_keys WHERE user_id = ?",
+		"DELETE FROM refresh_tokens WHERE user_id = ?",
+	} {
+		if _, err := tx.ExecContext(ctx, stmt, userID); err != nil {
+			return fmt.Errorf("could not delete user data: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM bookmarks WHERE user_id = ?", userID)
 	if err != nil {
 		return fmt.Errorf("could not delete bookmarks: %w", err)
 	}
 
 	// delete the user
-	_, err = tx.Exec("DELETE FROM users WHERE id = ?", userID)
+	_, err = tx.ExecContext(ctx, "DELETE FROM users WHERE id = ?", userID)
 	if err != nil {
 		return fmt.Errorf("could not delete user: %w", err)
 	}